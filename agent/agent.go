@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pivotal-golang/lager"
+	"github.com/sourcegraph/jsonrpc2"
+	jsonrpc2ws "github.com/sourcegraph/jsonrpc2/websocket"
+
+	"github.com/winston-ci/prole/builder"
+)
+
+const minBackoff = time.Second
+const maxBackoff = time.Minute
+
+// Registration is sent once per connection so the server knows what kind
+// of work this agent can take on, and how much of it at once.
+type Registration struct {
+	Platform string `json:"platform"`
+	MaxProcs int    `json:"max_procs"`
+}
+
+// Agent dials out to a server and waits to be handed builds over a
+// bidirectional jsonrpc2 connection, rather than the server having to
+// reach back in to it. This lets agents live behind NAT.
+type Agent struct {
+	logger lager.Logger
+
+	serverAddr string
+	platform   string
+	maxProcs   int
+
+	builder *builder.Builder
+}
+
+func New(
+	logger lager.Logger,
+	serverAddr string,
+	platform string,
+	maxProcs int,
+	builder *builder.Builder,
+) *Agent {
+	return &Agent{
+		logger: logger,
+
+		serverAddr: serverAddr,
+		platform:   platform,
+		maxProcs:   maxProcs,
+
+		builder: builder,
+	}
+}
+
+// Run dials the server, reconnecting with backoff if the connection
+// drops, until signaled to stop.
+func (agent *Agent) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	backoff := minBackoff
+
+	for {
+		conn, err := agent.connect()
+		if err != nil {
+			agent.logger.Error("failed-to-connect", err)
+
+			select {
+			case <-time.After(backoff):
+				backoff = nextBackoff(backoff)
+				continue
+			case <-signals:
+				return nil
+			}
+		}
+
+		backoff = minBackoff
+
+		done := make(chan struct{})
+		go func() {
+			<-conn.DisconnectNotify()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			agent.logger.Info("disconnected")
+		case <-signals:
+			conn.Close()
+			return nil
+		}
+	}
+}
+
+func (agent *Agent) connect() (*jsonrpc2.Conn, error) {
+	log := agent.logger.Session("connect", lager.Data{"server": agent.serverAddr})
+
+	ws, _, err := websocket.DefaultDialer.Dial(agent.serverAddr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := jsonrpc2.NewConn(
+		context.Background(),
+		jsonrpc2ws.NewObjectStream(ws),
+		jsonrpc2.AsyncHandler(newHandler(agent.logger, agent.builder, agent.maxProcs)),
+	)
+
+	err = conn.Notify(context.Background(), "Register", Registration{
+		Platform: agent.platform,
+		MaxProcs: agent.maxProcs,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	log.Info("registered")
+
+	return conn, nil
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	next := backoff * 2
+	if next > maxBackoff {
+		return maxBackoff
+	}
+
+	return next
+}
@@ -0,0 +1,16 @@
+package agent
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("nextBackoff", func() {
+	It("doubles, up to a cap", func() {
+		Ω(nextBackoff(time.Second)).Should(Equal(2 * time.Second))
+		Ω(nextBackoff(30 * time.Second)).Should(Equal(maxBackoff))
+		Ω(nextBackoff(maxBackoff)).Should(Equal(maxBackoff))
+	})
+})
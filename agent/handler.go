@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/winston-ci/prole/api/builds"
+	"github.com/winston-ci/prole/builder"
+	"github.com/winston-ci/prole/event"
+)
+
+// buildResult is the RPC response for a "Build" call.
+type buildResult struct {
+	Succeeded bool `json:"succeeded"`
+}
+
+type handler struct {
+	logger  lager.Logger
+	builder *builder.Builder
+
+	// slots bounds how many builds this agent runs at once, using a
+	// buffered channel as a simple counting semaphore. A nil channel
+	// (maxProcs <= 0) means unbounded.
+	slots chan struct{}
+}
+
+func newHandler(logger lager.Logger, builder *builder.Builder, maxProcs int) jsonrpc2.Handler {
+	var slots chan struct{}
+	if maxProcs > 0 {
+		slots = make(chan struct{}, maxProcs)
+	}
+
+	return &handler{
+		logger:  logger,
+		builder: builder,
+
+		slots: slots,
+	}
+}
+
+// Handle runs an assigned build and replies with whether it succeeded.
+// Unrecognized methods (e.g. notifications the agent doesn't care about)
+// are ignored, since the connection is shared for other traffic too.
+func (h *handler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	if req.Method != "Build" {
+		return
+	}
+
+	log := h.logger.Session("build")
+
+	var build builds.Build
+	err := json.Unmarshal(*req.Params, &build)
+	if err != nil {
+		log.Error("malformed-build", err)
+
+		if req.Notif {
+			return
+		}
+
+		conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if h.slots != nil {
+		h.slots <- struct{}{}
+		defer func() { <-h.slots }()
+	}
+
+	// the agent dials out over jsonrpc2 rather than serving the build's
+	// events over HTTP, so there's no one subscribed to this hub; it just
+	// gives the builder somewhere to report the build's effective config
+	hub := event.NewHub()
+	defer hub.Close()
+
+	succeeded, err := h.builder.Build(&build, hub)
+	if err != nil {
+		log.Error("failed", err)
+
+		if req.Notif {
+			return
+		}
+
+		conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInternalError,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	log.Info("done", lager.Data{"succeeded": succeeded})
+
+	if req.Notif {
+		return
+	}
+
+	conn.Reply(ctx, req.ID, buildResult{Succeeded: succeeded})
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/winston-ci/prole/api/artifactserver"
+	"github.com/winston-ci/prole/api/check"
+	"github.com/winston-ci/prole/api/eventserver"
+	"github.com/winston-ci/prole/artifact"
+	"github.com/winston-ci/prole/checker"
+	"github.com/winston-ci/prole/scheduler"
+)
+
+func New(
+	logger lager.Logger,
+	scheduler *scheduler.Scheduler,
+	checker *checker.Checker,
+	artifacts artifact.Repository,
+	drain <-chan struct{},
+) (http.Handler, error) {
+	mux := http.NewServeMux()
+
+	mux.Handle("/builds", scheduler)
+	mux.Handle("/builds/", eventserver.NewHandler(logger, scheduler))
+	mux.Handle("/checks", check.NewHandler(logger, checker, drain))
+	mux.Handle("/artifacts", artifactserver.NewCreateHandler(logger, artifacts))
+	mux.Handle("/artifacts/", artifactserver.NewGetHandler(logger, artifacts))
+
+	return mux, nil
+}
@@ -0,0 +1,46 @@
+package artifactserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/winston-ci/prole/artifact"
+)
+
+type CreateHandler struct {
+	logger lager.Logger
+
+	repo artifact.Repository
+}
+
+type CreateResponse struct {
+	Guid string `json:"guid"`
+}
+
+func NewCreateHandler(logger lager.Logger, repo artifact.Repository) *CreateHandler {
+	return &CreateHandler{
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+func (handler *CreateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := handler.logger.Session("create-artifact")
+
+	log.Info("creating")
+
+	guid, err := handler.repo.Create(r.Body)
+	if err != nil {
+		log.Error("failed-to-create", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	log.Info("created", lager.Data{"guid": guid})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateResponse{Guid: guid})
+}
@@ -0,0 +1,51 @@
+package artifactserver
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/winston-ci/prole/artifact"
+)
+
+type GetHandler struct {
+	logger lager.Logger
+
+	repo artifact.Repository
+}
+
+func NewGetHandler(logger lager.Logger, repo artifact.Repository) *GetHandler {
+	return &GetHandler{
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+func (handler *GetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	guid := strings.TrimPrefix(r.URL.Path, "/artifacts/")
+
+	log := handler.logger.Session("get-artifact", lager.Data{"guid": guid})
+
+	log.Info("fetching")
+
+	stream, err := handler.repo.Get(guid)
+	if err != nil {
+		if err == artifact.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		log.Error("failed-to-get", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", "application/x-gzip")
+
+	io.Copy(w, stream)
+}
@@ -0,0 +1,50 @@
+package builds
+
+import "errors"
+
+type Build struct {
+	Guid string `json:"guid"`
+
+	Image string `json:"image"`
+
+	Script string `json:"script"`
+
+	Privileged bool `json:"privileged"`
+
+	Source BuildSource `json:"source"`
+
+	Params map[string]string `json:"params"`
+
+	// Config is merged on top of the in-repo build.yml (if any) found in
+	// the fetched source, taking precedence over it. It lets a scheduler
+	// override a build's image, script, or inputs without a repo change.
+	Config Config `json:"config"`
+}
+
+type BuildSource struct {
+	Type string `json:"type"`
+
+	URI  string `json:"uri"`
+	Path string `json:"path"`
+
+	// Artifact is the handle of a previously-uploaded artifact, used when
+	// Type is "artifact".
+	Artifact string `json:"artifact"`
+
+	Params map[string]string `json:"params"`
+}
+
+var ErrMissingArtifactHandle = errors.New("artifact source is missing an artifact handle")
+var ErrMissingPath = errors.New("build source is missing a path")
+
+func (source BuildSource) Validate() error {
+	if source.Path == "" {
+		return ErrMissingPath
+	}
+
+	if source.Type == "artifact" && source.Artifact == "" {
+		return ErrMissingArtifactHandle
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package builds
+
+// Config is a build's declarative definition: what image to run it in,
+// what to run, and what its inputs are. It's merged from two sources: an
+// in-repo build.yml, and whatever the scheduler provides on the Build
+// itself, with the scheduler's values taking precedence.
+type Config struct {
+	Image string `yaml:"image,omitempty"`
+
+	Params map[string]string `yaml:"params,omitempty"`
+
+	Run RunConfig `yaml:"run,omitempty"`
+
+	Inputs []InputConfig `yaml:"inputs,omitempty"`
+}
+
+type RunConfig struct {
+	Path string   `yaml:"path"`
+	Args []string `yaml:"args,omitempty"`
+}
+
+type InputConfig struct {
+	Name string `yaml:"name"`
+
+	// Path is where this input is copied to inside the container. If
+	// empty, it's copied to the build source's own Path.
+	Path string `yaml:"path,omitempty"`
+}
+
+// Merge layers other on top of config: other's Image, Run, and Inputs
+// completely replace config's if set, while Params are merged key by
+// key, with other's values winning on conflicts.
+func (config Config) Merge(other Config) Config {
+	merged := config
+
+	merged.Params = mergeParams(config.Params, other.Params)
+
+	if other.Image != "" {
+		merged.Image = other.Image
+	}
+
+	if other.Run.Path != "" {
+		merged.Run = other.Run
+	}
+
+	if other.Inputs != nil {
+		merged.Inputs = other.Inputs
+	}
+
+	return merged
+}
+
+func mergeParams(base, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string)
+
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range override {
+		merged[k] = v
+	}
+
+	return merged
+}
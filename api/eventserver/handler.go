@@ -0,0 +1,87 @@
+package eventserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/winston-ci/prole/event"
+)
+
+// HubRepository looks up the event.Hub for a given build, so that it can
+// be subscribed to over HTTP.
+type HubRepository interface {
+	HubFor(buildID string) (*event.Hub, bool)
+}
+
+type Handler struct {
+	logger lager.Logger
+
+	hubs HubRepository
+}
+
+func NewHandler(logger lager.Logger, hubs HubRepository) *Handler {
+	return &Handler{
+		logger: logger,
+		hubs:   hubs,
+	}
+}
+
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	buildID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/builds/"), "/events")
+
+	log := handler.logger.Session("events", lager.Data{"build": buildID})
+
+	hub, found := handler.hubs.HubFor(buildID)
+	if !found {
+		log.Info("not-found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	from, err := parseFrom(r)
+	if err != nil {
+		log.Error("malformed-last-event-id", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if strings.ToLower(r.Header.Get("Upgrade")) == "websocket" {
+		handler.serveWebSocket(w, r, hub, from)
+		return
+	}
+
+	handler.serveSSE(w, r, hub, from, log)
+}
+
+// parseFrom resolves the index to resume streaming from, preferring the
+// standard SSE reconnection header over an explicit query param.
+//
+// Last-Event-ID names the last event the client already saw, per the SSE
+// reconnection spec, so it's offset by one to resume after it. The
+// `from` query param instead names the Hub.Subscribe index to resume at
+// directly, and is passed through as-is.
+func parseFrom(r *http.Request) (uint, error) {
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		from, err := strconv.Atoi(lastEventID)
+		if err != nil {
+			return 0, err
+		}
+
+		return uint(from) + 1, nil
+	}
+
+	fromParam := r.URL.Query().Get("from")
+	if fromParam == "" {
+		return 0, nil
+	}
+
+	from, err := strconv.Atoi(fromParam)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(from), nil
+}
@@ -0,0 +1,119 @@
+package eventserver_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/winston-ci/prole/api/eventserver"
+	"github.com/winston-ci/prole/event"
+)
+
+type logEvent struct {
+	Payload string `json:"payload"`
+}
+
+func (logEvent) EventType() event.EventType { return "log" }
+
+type fakeHubRepository struct {
+	hub   *event.Hub
+	found bool
+}
+
+func (f fakeHubRepository) HubFor(buildID string) (*event.Hub, bool) {
+	return f.hub, f.found
+}
+
+var _ = Describe("Handler", func() {
+	var hub *event.Hub
+	var handler *eventserver.Handler
+
+	BeforeEach(func() {
+		hub = event.NewHub()
+
+		handler = eventserver.NewHandler(
+			lagertest.NewTestLogger("test"),
+			fakeHubRepository{hub: hub, found: true},
+		)
+	})
+
+	It("streams already-emitted events as SSE frames", func() {
+		hub.EmitEvent(logEvent{Payload: "hello"})
+		hub.Close()
+
+		req, err := http.NewRequest("GET", "/builds/some-build/events", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		reader := bufio.NewReader(strings.NewReader(recorder.Body.String()))
+
+		line, err := reader.ReadString('\n')
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(line).Should(Equal("id: 0\n"))
+
+		line, err = reader.ReadString('\n')
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(line).Should(Equal("event: log\n"))
+
+		line, err = reader.ReadString('\n')
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(line).Should(Equal(`data: {"payload":"hello"}` + "\n"))
+	})
+
+	It("resumes from the Last-Event-ID header's index, exclusive", func() {
+		hub.EmitEvent(logEvent{Payload: "hello"})
+		hub.EmitEvent(logEvent{Payload: "world"})
+		hub.Close()
+
+		req, err := http.NewRequest("GET", "/builds/some-build/events", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Last-Event-ID", "0")
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		Ω(recorder.Body.String()).Should(ContainSubstring(`data: {"payload":"world"}`))
+		Ω(recorder.Body.String()).ShouldNot(ContainSubstring(`data: {"payload":"hello"}`))
+	})
+
+	It("resumes from the ?from= query param's index, inclusive", func() {
+		hub.EmitEvent(logEvent{Payload: "hello"})
+		hub.EmitEvent(logEvent{Payload: "world"})
+		hub.Close()
+
+		req, err := http.NewRequest("GET", "/builds/some-build/events?from=0", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		Ω(recorder.Body.String()).Should(ContainSubstring(`data: {"payload":"hello"}`))
+		Ω(recorder.Body.String()).Should(ContainSubstring(`data: {"payload":"world"}`))
+	})
+
+	Context("when the build has no hub", func() {
+		BeforeEach(func() {
+			handler = eventserver.NewHandler(
+				lagertest.NewTestLogger("test"),
+				fakeHubRepository{found: false},
+			)
+		})
+
+		It("404s", func() {
+			req, err := http.NewRequest("GET", "/builds/some-build/events", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Ω(recorder.Code).Should(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,70 @@
+package eventserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/winston-ci/prole/event"
+)
+
+// serveSSE pumps hub's events out as Server-Sent Events, resuming from the
+// given index, and returns once the client disconnects or the hub closes.
+func (handler *Handler) serveSSE(w http.ResponseWriter, r *http.Request, hub *event.Hub, from uint, log lager.Logger) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := make(chan event.Event)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go hub.Subscribe(from, events, stop)
+
+	var closed <-chan bool
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		closed = notifier.CloseNotify()
+	}
+
+	id := from
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if e == nil {
+				// the hub was closed without a final event occurring
+				continue
+			}
+
+			payload, err := json.Marshal(e)
+			if err != nil {
+				log.Error("failed-to-marshal-event", err)
+				continue
+			}
+
+			fmt.Fprintf(w, "id: %d\n", id)
+			fmt.Fprintf(w, "event: %s\n", e.EventType())
+			fmt.Fprintf(w, "data: %s\n\n", payload)
+			flusher.Flush()
+
+			id++
+
+		case <-closed:
+			return
+		}
+	}
+}
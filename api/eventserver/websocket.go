@@ -0,0 +1,61 @@
+package eventserver
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/winston-ci/prole/event"
+)
+
+// wireEvent is the JSON-over-websocket equivalent of an SSE frame: the
+// same (id, type, payload) triple, just without the text framing.
+type wireEvent struct {
+	ID      uint            `json:"id"`
+	Type    event.EventType `json:"type"`
+	Payload event.Event     `json:"event"`
+}
+
+// upgrader has no origin restrictions: prole's event stream carries
+// nothing sensitive, and is typically proxied by the scheduler rather
+// than hit directly by browsers.
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// serveWebSocket pumps hub's events out as JSON frames over a websocket
+// connection, for clients that would rather not parse SSE framing.
+func (handler *Handler) serveWebSocket(w http.ResponseWriter, r *http.Request, hub *event.Hub, from uint) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	events := make(chan event.Event)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go hub.Subscribe(from, events, stop)
+
+	id := from
+
+	for e := range events {
+		if e == nil {
+			// the hub was closed without a final event occurring
+			continue
+		}
+
+		err := conn.WriteJSON(wireEvent{
+			ID:      id,
+			Type:    e.EventType(),
+			Payload: e,
+		})
+		if err != nil {
+			return
+		}
+
+		id++
+	}
+}
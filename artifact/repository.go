@@ -0,0 +1,130 @@
+package artifact
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nu7hatch/gouuid"
+)
+
+var ErrNotFound = errors.New("artifact not found")
+
+// Repository stores uploaded build source tarballs, keyed by an opaque
+// handle, so that they can later be streamed into a build's container in
+// place of fetching from a resource.
+type Repository interface {
+	// Create gzips src (a plain tar stream) and persists it under a new
+	// handle, which it returns.
+	Create(src io.Reader) (string, error)
+
+	// Get returns a reader for the tarball previously stored under handle.
+	Get(handle string) (io.ReadCloser, error)
+
+	// Collect deletes any artifacts whose TTL has elapsed.
+	Collect()
+}
+
+type diskRepository struct {
+	dir string
+	ttl time.Duration
+
+	lock     sync.Mutex
+	expireAt map[string]time.Time
+}
+
+// NewDiskRepository stores artifacts as tarballs on disk, under dir, each
+// expiring ttl after it was created. A ttl of 0 means artifacts are kept
+// forever. Expiry is tracked in memory for artifacts created through the
+// returned Repository only; tarballs already in dir are never collected.
+func NewDiskRepository(dir string, ttl time.Duration) (Repository, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diskRepository{
+		dir: dir,
+		ttl: ttl,
+
+		expireAt: make(map[string]time.Time),
+	}, nil
+}
+
+func (repo *diskRepository) Create(src io.Reader) (string, error) {
+	guid, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	handle := guid.String()
+
+	dest, err := os.Create(repo.path(handle))
+	if err != nil {
+		return "", err
+	}
+
+	defer dest.Close()
+
+	gzWriter := gzip.NewWriter(dest)
+
+	_, err = io.Copy(gzWriter, src)
+	if err != nil {
+		return "", err
+	}
+
+	err = gzWriter.Close()
+	if err != nil {
+		return "", err
+	}
+
+	if repo.ttl != 0 {
+		repo.lock.Lock()
+		repo.expireAt[handle] = time.Now().Add(repo.ttl)
+		repo.lock.Unlock()
+	}
+
+	return handle, nil
+}
+
+func (repo *diskRepository) Get(handle string) (io.ReadCloser, error) {
+	src, err := os.Open(repo.path(handle))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return src, nil
+}
+
+func (repo *diskRepository) Collect() {
+	now := time.Now()
+
+	repo.lock.Lock()
+	expired := make([]string, 0)
+	for handle, expiresAt := range repo.expireAt {
+		if now.After(expiresAt) {
+			expired = append(expired, handle)
+		}
+	}
+	repo.lock.Unlock()
+
+	for _, handle := range expired {
+		os.Remove(repo.path(handle))
+
+		repo.lock.Lock()
+		delete(repo.expireAt, handle)
+		repo.lock.Unlock()
+	}
+}
+
+func (repo *diskRepository) path(handle string) string {
+	return filepath.Join(repo.dir, handle+".tar.gz")
+}
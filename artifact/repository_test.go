@@ -0,0 +1,71 @@
+package artifact_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/winston-ci/prole/artifact"
+)
+
+var _ = Describe("DiskRepository", func() {
+	var dir string
+	var repo artifact.Repository
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "artifact-repository")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		repo, err = artifact.NewDiskRepository(dir, time.Hour)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("round-trips an uploaded tar stream, gzipped, by its handle", func() {
+		handle, err := repo.Create(bytes.NewBufferString("some-tarball"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(handle).ShouldNot(BeEmpty())
+
+		stream, err := repo.Get(handle)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		gzReader, err := gzip.NewReader(stream)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		contents, err := ioutil.ReadAll(gzReader)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(contents)).Should(Equal("some-tarball"))
+	})
+
+	It("returns ErrNotFound for an unknown handle", func() {
+		_, err := repo.Get("some-bogus-handle")
+		Ω(err).Should(Equal(artifact.ErrNotFound))
+	})
+
+	Context("when an artifact's TTL has elapsed", func() {
+		JustBeforeEach(func() {
+			var err error
+			repo, err = artifact.NewDiskRepository(dir, time.Millisecond)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("is removed by Collect", func() {
+			handle, err := repo.Create(bytes.NewBufferString("some-tarball"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			time.Sleep(10 * time.Millisecond)
+
+			repo.Collect()
+
+			_, err = repo.Get(handle)
+			Ω(err).Should(Equal(artifact.ErrNotFound))
+		})
+	})
+})
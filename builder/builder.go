@@ -0,0 +1,241 @@
+package builder
+
+import (
+	"sort"
+	"time"
+
+	"github.com/winston-ci/prole/api/builds"
+	"github.com/winston-ci/prole/artifact"
+	"github.com/winston-ci/prole/event"
+	"github.com/winston-ci/prole/worker"
+)
+
+// SourceFetcher fetches a build's source onto disk, returning the path it
+// was fetched to, for source kinds backed by a resource (e.g. git, raw).
+type SourceFetcher interface {
+	Fetch(builds.BuildSource) (string, error)
+}
+
+// Outputter performs a build's outputs once its script has run.
+type Outputter interface {
+	PerformOutputs(handle string, build builds.Build) error
+}
+
+// EventEmitter receives events as a build progresses, for streaming out
+// to whoever's watching it (e.g. over SSE).
+type EventEmitter interface {
+	EmitEvent(event.Event)
+}
+
+type Builder struct {
+	sourceFetcher SourceFetcher
+	outputter     Outputter
+	backend       worker.Backend
+
+	artifacts artifact.Repository
+
+	containerGraceTime time.Duration
+}
+
+func NewBuilder(
+	sourceFetcher SourceFetcher,
+	outputter Outputter,
+	backend worker.Backend,
+	artifacts artifact.Repository,
+	containerGraceTime time.Duration,
+) *Builder {
+	return &Builder{
+		sourceFetcher: sourceFetcher,
+		outputter:     outputter,
+		backend:       backend,
+
+		artifacts: artifacts,
+
+		containerGraceTime: containerGraceTime,
+	}
+}
+
+func (builder *Builder) Build(build *builds.Build, events EventEmitter) (bool, error) {
+	err := build.Source.Validate()
+	if err != nil {
+		return false, err
+	}
+
+	fetchedSource, err := builder.fetch(build.Source)
+	if err != nil {
+		return false, err
+	}
+
+	repoConfig, err := loadConfig(fetchedSource)
+	if err != nil {
+		return false, err
+	}
+
+	// the scheduler-provided config takes precedence over whatever the
+	// repo itself declares
+	config := repoConfig.Merge(build.Config)
+
+	events.EmitEvent(event.Config{Config: config})
+
+	image := build.Image
+	if config.Image != "" {
+		image = config.Image
+	}
+
+	script := build.Script
+	var args []string
+	if config.Run.Path != "" {
+		script = config.Run.Path
+		args = config.Run.Args
+	}
+
+	container, err := builder.backend.Create(worker.ContainerSpec{
+		Image: image,
+		Properties: worker.Properties{
+			worker.OwnerProperty: worker.OwnerPropertyValue,
+		},
+		GraceTime: builder.containerGraceTime,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// prole fetches a single build source, so a declared input doesn't
+	// pick which source to fetch; it just names another path the one
+	// fetched source should land at, letting build.yml spread it across
+	// several container paths (e.g. for steps that each expect it
+	// somewhere different)
+	destinations := inputDestinations(config.Inputs, build.Source.Path)
+
+	for _, destination := range destinations {
+		sourceStream, err := worker.TarDirectory(fetchedSource)
+		if err != nil {
+			return false, err
+		}
+
+		err = container.StreamIn(destination, sourceStream)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	process, err := container.Run(worker.ProcessSpec{
+		Path: script,
+		Args: args,
+		Env:  paramsToEnv(config.Params),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	stopKeepingAlive := builder.keepAlive(container)
+	defer close(stopKeepingAlive)
+
+	succeeded := false
+
+	for payload := range process.Stream() {
+		if payload.ExitStatus != nil {
+			succeeded = *payload.ExitStatus == 0
+			continue
+		}
+
+		events.EmitEvent(event.Log{Payload: string(payload.Data)})
+	}
+
+	if succeeded {
+		err = builder.outputter.PerformOutputs(container.Handle(), *build)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return succeeded, nil
+}
+
+// keepAlive polls the container's properties while the build's process is
+// running, resetting its GraceTime so that a long-running build isn't
+// reaped out from under it. Stop by closing the returned channel.
+func (builder *Builder) keepAlive(container worker.Container) chan<- struct{} {
+	stop := make(chan struct{})
+
+	if builder.containerGraceTime <= 0 {
+		return stop
+	}
+
+	go func() {
+		ticker := time.NewTicker(builder.containerGraceTime / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				container.Properties()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return stop
+}
+
+// fetch resolves a build's source onto disk. Sources of kind "artifact"
+// are streamed out of the artifact repository rather than fetched via a
+// resource container, so that uploaded, ad-hoc build inputs (e.g. from
+// `fly execute`) don't need a resource of their own.
+func (builder *Builder) fetch(source builds.BuildSource) (string, error) {
+	if source.Type == "artifact" {
+		return builder.fetchArtifact(source)
+	}
+
+	return builder.sourceFetcher.Fetch(source)
+}
+
+func (builder *Builder) fetchArtifact(source builds.BuildSource) (string, error) {
+	artifactStream, err := builder.artifacts.Get(source.Artifact)
+	if err != nil {
+		return "", err
+	}
+
+	defer artifactStream.Close()
+
+	return extractTarball(artifactStream)
+}
+
+// paramsToEnv renders a build's merged params as "NAME=value" environment
+// entries, sorted by name for deterministic ordering, so that the values
+// a scheduler or build.yml supplies actually reach the build's script.
+func paramsToEnv(params map[string]string) []string {
+	if len(params) == 0 {
+		return nil
+	}
+
+	env := make([]string, 0, len(params))
+	for name, value := range params {
+		env = append(env, name+"="+value)
+	}
+
+	sort.Strings(env)
+
+	return env
+}
+
+// inputDestinations returns the container paths the fetched build source
+// should be copied to: one per declared input (falling back to the
+// source's own Path for any input that doesn't declare its own), or just
+// the source's Path if build.yml declares no inputs at all.
+func inputDestinations(inputs []builds.InputConfig, sourcePath string) []string {
+	if len(inputs) == 0 {
+		return []string{sourcePath}
+	}
+
+	destinations := make([]string, len(inputs))
+	for i, input := range inputs {
+		destinations[i] = input.Path
+		if destinations[i] == "" {
+			destinations[i] = sourcePath
+		}
+	}
+
+	return destinations
+}
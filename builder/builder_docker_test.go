@@ -0,0 +1,88 @@
+package builder_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/winston-ci/prole/api/builds"
+	. "github.com/winston-ci/prole/builder"
+	"github.com/winston-ci/prole/worker"
+)
+
+// These tests exercise the Builder against a real container backend,
+// rather than worker/fakeworker, to guard against the fakes drifting from
+// how an actual Backend behaves. They're skipped if there's no Docker
+// daemon reachable at dockerAddr.
+var _ = Describe("Builder against the docker backend", func() {
+	const dockerAddr = "unix:///var/run/docker.sock"
+
+	var backend worker.Backend
+
+	var sourceFetcher *fakeSourceFetcher
+	var outputter *fakeOutputter
+	var events *fakeEventEmitter
+	var builder *Builder
+
+	var build *builds.Build
+	var sourceDir string
+
+	BeforeEach(func() {
+		var err error
+		backend, err = worker.NewDockerBackend(dockerAddr)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		if _, err := backend.Containers(worker.Properties{}); err != nil {
+			Skip("no docker daemon reachable at " + dockerAddr + ": " + err.Error())
+		}
+
+		sourceDir, err = ioutil.TempDir("", "prole-builder-docker-test")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		sourceFetcher = &fakeSourceFetcher{FetchResult: sourceDir}
+		outputter = &fakeOutputter{}
+		events = &fakeEventEmitter{}
+
+		builder = NewBuilder(sourceFetcher, outputter, backend, nil, 0)
+
+		build = &builds.Build{
+			Image: "busybox",
+
+			Script: "true",
+
+			Source: builds.BuildSource{
+				Type: "raw",
+				URI:  "http://example.com/foo.tar.gz",
+				Path: "source",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(sourceDir)
+	})
+
+	It("runs the build's script in a real container", func() {
+		succeeded, err := builder.Build(build, events)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(succeeded).Should(BeTrue())
+
+		Ω(outputter.PerformedOutputsFor()).Should(ContainElement(*build))
+	})
+
+	Context("when the build's script fails", func() {
+		BeforeEach(func() {
+			build.Script = "false"
+		})
+
+		It("returns false without performing the outputs", func() {
+			succeeded, err := builder.Build(build, events)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(succeeded).Should(BeFalse())
+
+			Ω(outputter.PerformedOutputsFor()).Should(BeEmpty())
+		})
+	})
+})
@@ -2,49 +2,93 @@ package builder_test
 
 import (
 	"errors"
+	"io"
+	"io/ioutil"
+	"os"
 
-	"github.com/cloudfoundry-incubator/executor/log_streamer"
-	"github.com/cloudfoundry-incubator/executor/log_streamer/fake_log_streamer"
-	"github.com/cloudfoundry-incubator/garden/client/connection/fake_connection"
-	"github.com/cloudfoundry-incubator/garden/client/fake_warden_client"
-	"github.com/cloudfoundry-incubator/garden/warden"
-	"github.com/cloudfoundry-incubator/runtime-schema/models"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/winston-ci/prole/api/builds"
 	. "github.com/winston-ci/prole/builder"
-	"github.com/winston-ci/prole/sourcefetcher/fakesourcefetcher"
+	"github.com/winston-ci/prole/event"
+	"github.com/winston-ci/prole/worker"
+	"github.com/winston-ci/prole/worker/fakeworker"
 )
 
+type fakeSourceFetcher struct {
+	FetchResult string
+	FetchError  error
+
+	fetched []builds.BuildSource
+}
+
+func (fetcher *fakeSourceFetcher) Fetch(source builds.BuildSource) (string, error) {
+	fetcher.fetched = append(fetcher.fetched, source)
+
+	if fetcher.FetchError != nil {
+		return "", fetcher.FetchError
+	}
+
+	return fetcher.FetchResult, nil
+}
+
+func (fetcher *fakeSourceFetcher) Fetched() []builds.BuildSource {
+	return fetcher.fetched
+}
+
+type fakeOutputter struct {
+	PerformOutputsError error
+
+	performedOutputsFor []builds.Build
+}
+
+func (outputter *fakeOutputter) PerformOutputs(handle string, build builds.Build) error {
+	outputter.performedOutputsFor = append(outputter.performedOutputsFor, build)
+	return outputter.PerformOutputsError
+}
+
+func (outputter *fakeOutputter) PerformedOutputsFor() []builds.Build {
+	return outputter.performedOutputsFor
+}
+
+type fakeEventEmitter struct {
+	events []event.Event
+}
+
+func (emitter *fakeEventEmitter) EmitEvent(e event.Event) {
+	emitter.events = append(emitter.events, e)
+}
+
 var _ = Describe("Builder", func() {
-	var sourceFetcher *fakesourcefetcher.Fetcher
-	var wardenClient *fake_warden_client.FakeClient
-	var logStreamer *fake_log_streamer.FakeLogStreamer
+	var backend *fakeworker.FakeBackend
+	var container *fakeworker.FakeContainer
+
+	var sourceFetcher *fakeSourceFetcher
+	var outputter *fakeOutputter
+	var events *fakeEventEmitter
 	var builder *Builder
 
 	var build *builds.Build
+	var sourceDir string
 
-	primedStream := func(payloads ...warden.ProcessStream) <-chan warden.ProcessStream {
-		stream := make(chan warden.ProcessStream, len(payloads))
-
-		for _, payload := range payloads {
-			stream <- payload
-		}
+	BeforeEach(func() {
+		var err error
+		sourceDir, err = ioutil.TempDir("", "prole-builder-test")
+		Ω(err).ShouldNot(HaveOccurred())
 
-		close(stream)
+		backend = fakeworker.New()
+		container = fakeworker.NewFakeContainer("some-handle")
 
-		return stream
-	}
+		backend.WhenCreating = func(worker.ContainerSpec) (worker.Container, error) {
+			return container, nil
+		}
 
-	BeforeEach(func() {
-		sourceFetcher = fakesourcefetcher.New()
-		wardenClient = fake_warden_client.New()
-		logStreamer = fake_log_streamer.New()
+		sourceFetcher = &fakeSourceFetcher{FetchResult: sourceDir}
+		outputter = &fakeOutputter{}
+		events = &fakeEventEmitter{}
 
-		builder = NewBuilder(sourceFetcher, wardenClient, func(models.LogConfig) log_streamer.LogStreamer {
-			return logStreamer
-		})
+		builder = NewBuilder(sourceFetcher, outputter, backend, nil, 0)
 
 		build = &builds.Build{
 			Image: "some-image-name",
@@ -57,123 +101,194 @@ var _ = Describe("Builder", func() {
 				Path: "some/source/path",
 			},
 		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(sourceDir)
+	})
+
+	It("fetches the build source and copies it in to the container", func() {
+		_, err := builder.Build(build, events)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(sourceFetcher.Fetched()).Should(ContainElement(build.Source))
 
-		exitStatus := uint32(0)
+		Ω(container.StreamedIn()).Should(ContainElement("some/source/path"))
+	})
 
-		successfulStream := primedStream(warden.ProcessStream{
-			ExitStatus: &exitStatus,
+	Context("when the build's config declares a single input", func() {
+		BeforeEach(func() {
+			build.Config.Inputs = []builds.InputConfig{
+				{Name: "some-input", Path: "some/input/path"},
+			}
 		})
 
-		wardenClient.Connection.WhenRunning = func(handle string, spec warden.ProcessSpec) (uint32, <-chan warden.ProcessStream, error) {
-			return 42, successfulStream, nil
-		}
+		It("copies the build source in to the input's path instead", func() {
+			_, err := builder.Build(build, events)
+			Ω(err).ShouldNot(HaveOccurred())
 
-		wardenClient.Connection.WhenCreating = func(warden.ContainerSpec) (string, error) {
-			return "some-handle", nil
-		}
+			Ω(container.StreamedIn()).Should(ContainElement("some/input/path"))
+		})
 	})
 
-	It("fetches the build source and copies it in to the container", func() {
-		sourceFetcher.FetchResult = "/path/on/disk"
-
-		_, err := builder.Build(build)
-		Ω(err).ShouldNot(HaveOccurred())
+	Context("when the build's config declares more than one input", func() {
+		BeforeEach(func() {
+			build.Config.Inputs = []builds.InputConfig{
+				{Name: "some-input", Path: "some/input/path"},
+				{Name: "another-input", Path: "another/input/path"},
+				{Name: "unnamed-destination-input"},
+			}
+		})
 
-		Ω(sourceFetcher.Fetched()).Should(ContainElement(build.Source))
+		It("copies the build source in to each input's path", func() {
+			_, err := builder.Build(build, events)
+			Ω(err).ShouldNot(HaveOccurred())
 
-		Ω(wardenClient.Connection.CopiedIn("some-handle")).Should(ContainElement(fake_connection.CopyInSpec{
-			Source:      "/path/on/disk/",
-			Destination: "some/source/path/",
-		}))
+			Ω(container.StreamedIn()).Should(ConsistOf(
+				"some/input/path",
+				"another/input/path",
+				"some/source/path",
+			))
+		})
 	})
 
 	It("runs the build's script in the container", func() {
-		_, err := builder.Build(build)
-		Ω(err).ShouldNot(HaveOccurred())
+		var ranSpec worker.ProcessSpec
 
-		Ω(wardenClient.Connection.SpawnedProcesses("some-handle")).Should(ContainElement(warden.ProcessSpec{
-			Script: "./bin/test",
-		}))
-	})
+		container.WhenRunning = func(spec worker.ProcessSpec) (worker.Process, error) {
+			ranSpec = spec
 
-	It("emits the build's output", func() {
-		wardenClient.Connection.WhenRunning = func(handle string, spec warden.ProcessSpec) (uint32, <-chan warden.ProcessStream, error) {
 			exitStatus := uint32(0)
+			payloads := make(chan worker.ProcessPayload, 1)
+			payloads <- worker.ProcessPayload{ExitStatus: &exitStatus}
+			close(payloads)
 
-			successfulStream := primedStream(
-				warden.ProcessStream{
-					Source: warden.ProcessStreamSourceStdout,
-					Data:   []byte("stdout\n"),
-				},
-				warden.ProcessStream{
-					Source: warden.ProcessStreamSourceStderr,
-					Data:   []byte("stderr\n"),
-				},
-				warden.ProcessStream{
-					ExitStatus: &exitStatus,
-				},
-			)
-
-			return 42, successfulStream, nil
+			return fakeworker.NewFakeProcess(1, payloads), nil
 		}
 
-		_, err := builder.Build(build)
+		_, err := builder.Build(build, events)
 		Ω(err).ShouldNot(HaveOccurred())
 
-		Ω(logStreamer.StdoutBuffer.String()).Should(Equal("stdout\n"))
-		Ω(logStreamer.StderrBuffer.String()).Should(Equal("stderr\n"))
-		Ω(logStreamer.Flushed).Should(BeTrue())
+		Ω(ranSpec.Path).Should(Equal("./bin/test"))
 	})
 
-	Context("when running the build's script fails", func() {
-		disaster := errors.New("oh no!")
-
+	Context("when the build's config declares params", func() {
 		BeforeEach(func() {
-			wardenClient.Connection.WhenRunning = func(handle string, spec warden.ProcessSpec) (uint32, <-chan warden.ProcessStream, error) {
-				return 0, nil, disaster
+			build.Config.Params = map[string]string{
+				"FOO": "1",
+				"BAR": "2",
 			}
 		})
 
-		It("returns true", func() {
-			succeeded, err := builder.Build(build)
-			Ω(err).Should(Equal(disaster))
-			Ω(succeeded).Should(BeFalse())
+		It("passes them to the script as environment variables", func() {
+			var ranSpec worker.ProcessSpec
+
+			container.WhenRunning = func(spec worker.ProcessSpec) (worker.Process, error) {
+				ranSpec = spec
+
+				exitStatus := uint32(0)
+				payloads := make(chan worker.ProcessPayload, 1)
+				payloads <- worker.ProcessPayload{ExitStatus: &exitStatus}
+				close(payloads)
+
+				return fakeworker.NewFakeProcess(1, payloads), nil
+			}
+
+			_, err := builder.Build(build, events)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(ranSpec.Env).Should(Equal([]string{"BAR=2", "FOO=1"}))
 		})
 	})
 
+	It("emits the build's output as log events", func() {
+		container.WhenRunning = func(spec worker.ProcessSpec) (worker.Process, error) {
+			exitStatus := uint32(0)
+
+			payloads := make(chan worker.ProcessPayload, 3)
+			payloads <- worker.ProcessPayload{
+				Source: worker.ProcessStreamSourceStdout,
+				Data:   []byte("stdout\n"),
+			}
+			payloads <- worker.ProcessPayload{
+				Source: worker.ProcessStreamSourceStderr,
+				Data:   []byte("stderr\n"),
+			}
+			payloads <- worker.ProcessPayload{ExitStatus: &exitStatus}
+			close(payloads)
+
+			return fakeworker.NewFakeProcess(1, payloads), nil
+		}
+
+		_, err := builder.Build(build, events)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(events.events).Should(ContainElement(event.Log{Payload: "stdout\n"}))
+		Ω(events.events).Should(ContainElement(event.Log{Payload: "stderr\n"}))
+	})
+
 	Context("when the build's script exits 0", func() {
 		BeforeEach(func() {
-			wardenClient.Connection.WhenRunning = func(handle string, spec warden.ProcessSpec) (uint32, <-chan warden.ProcessStream, error) {
+			container.WhenRunning = func(spec worker.ProcessSpec) (worker.Process, error) {
 				exitStatus := uint32(0)
 
-				return 42, primedStream(warden.ProcessStream{
-					ExitStatus: &exitStatus,
-				}), nil
+				payloads := make(chan worker.ProcessPayload, 1)
+				payloads <- worker.ProcessPayload{ExitStatus: &exitStatus}
+				close(payloads)
+
+				return fakeworker.NewFakeProcess(1, payloads), nil
 			}
 		})
 
 		It("returns true", func() {
-			succeeded, err := builder.Build(build)
+			succeeded, err := builder.Build(build, events)
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(succeeded).Should(BeTrue())
 		})
+
+		It("performs the build's outputs", func() {
+			_, err := builder.Build(build, events)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(outputter.PerformedOutputsFor()).Should(ContainElement(*build))
+		})
 	})
 
 	Context("when the build's script exits nonzero", func() {
 		BeforeEach(func() {
-			wardenClient.Connection.WhenRunning = func(handle string, spec warden.ProcessSpec) (uint32, <-chan warden.ProcessStream, error) {
+			container.WhenRunning = func(spec worker.ProcessSpec) (worker.Process, error) {
 				exitStatus := uint32(2)
 
-				return 42, primedStream(warden.ProcessStream{
-					ExitStatus: &exitStatus,
-				}), nil
+				payloads := make(chan worker.ProcessPayload, 1)
+				payloads <- worker.ProcessPayload{ExitStatus: &exitStatus}
+				close(payloads)
+
+				return fakeworker.NewFakeProcess(1, payloads), nil
 			}
 		})
 
-		It("returns true", func() {
-			succeeded, err := builder.Build(build)
+		It("returns false, and does not perform the build's outputs", func() {
+			succeeded, err := builder.Build(build, events)
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(succeeded).Should(BeFalse())
+
+			Ω(outputter.PerformedOutputsFor()).Should(BeEmpty())
+		})
+	})
+
+	Context("when running the build's script fails", func() {
+		disaster := errors.New("oh no!")
+
+		BeforeEach(func() {
+			container.WhenRunning = func(spec worker.ProcessSpec) (worker.Process, error) {
+				return nil, disaster
+			}
+		})
+
+		It("returns the error", func() {
+			succeeded, err := builder.Build(build, events)
+			Ω(err).Should(Equal(disaster))
+			Ω(succeeded).Should(BeFalse())
 		})
 	})
 
@@ -181,13 +296,13 @@ var _ = Describe("Builder", func() {
 		disaster := errors.New("oh no!")
 
 		BeforeEach(func() {
-			wardenClient.Connection.WhenCreating = func(spec warden.ContainerSpec) (string, error) {
-				return "", disaster
+			backend.WhenCreating = func(worker.ContainerSpec) (worker.Container, error) {
+				return nil, disaster
 			}
 		})
 
 		It("returns the error", func() {
-			succeeded, err := builder.Build(build)
+			succeeded, err := builder.Build(build, events)
 			Ω(err).Should(Equal(disaster))
 			Ω(succeeded).Should(BeFalse())
 		})
@@ -201,7 +316,7 @@ var _ = Describe("Builder", func() {
 		})
 
 		It("returns the error", func() {
-			succeeded, err := builder.Build(build)
+			succeeded, err := builder.Build(build, events)
 			Ω(err).Should(Equal(disaster))
 			Ω(succeeded).Should(BeFalse())
 		})
@@ -211,15 +326,15 @@ var _ = Describe("Builder", func() {
 		disaster := errors.New("oh no!")
 
 		BeforeEach(func() {
-			wardenClient.Connection.WhenCopyingIn = func(handle string, src, dst string) error {
+			container.WhenStreamingIn = func(dst string, src io.Reader) error {
 				return disaster
 			}
 		})
 
 		It("returns the error", func() {
-			succeeded, err := builder.Build(build)
+			succeeded, err := builder.Build(build, events)
 			Ω(err).Should(Equal(disaster))
 			Ω(succeeded).Should(BeFalse())
 		})
 	})
-})
\ No newline at end of file
+})
@@ -0,0 +1,44 @@
+package builder
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/winston-ci/prole/api/builds"
+)
+
+// ConfigFileName is the well-known path, relative to a build's fetched
+// source, of its declarative build config.
+const ConfigFileName = "build.yml"
+
+// loadConfig reads a build's in-repo build.yml, if it has one. A build
+// with no build.yml is not an error; it just has a zero-value Config.
+func loadConfig(sourcePath string) (builds.Config, error) {
+	configFile, err := os.Open(filepath.Join(sourcePath, ConfigFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return builds.Config{}, nil
+		}
+
+		return builds.Config{}, err
+	}
+
+	defer configFile.Close()
+
+	contents, err := ioutil.ReadAll(configFile)
+	if err != nil {
+		return builds.Config{}, err
+	}
+
+	var config builds.Config
+
+	err = yaml.Unmarshal(contents, &config)
+	if err != nil {
+		return builds.Config{}, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,69 @@
+package builder
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// extractTarball unpacks a gzipped tarball from src into a fresh temporary
+// directory and returns its path.
+func extractTarball(src io.Reader) (string, error) {
+	dir, err := ioutil.TempDir("", "prole-artifact")
+	if err != nil {
+		return "", err
+	}
+
+	gzReader, err := gzip.NewReader(src)
+	if err != nil {
+		return "", err
+	}
+
+	tarReader := tar.NewReader(gzReader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		destPath := filepath.Join(dir, header.Name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(destPath, os.FileMode(header.Mode))
+		case tar.TypeReg, tar.TypeRegA:
+			err = extractFile(tarReader, destPath, os.FileMode(header.Mode))
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return dir, nil
+}
+
+func extractFile(src io.Reader, destPath string, mode os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(destPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	dest, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	defer dest.Close()
+
+	_, err = io.Copy(dest, src)
+	return err
+}
@@ -0,0 +1,12 @@
+package event
+
+import "github.com/winston-ci/prole/api/builds"
+
+// Config reports the effective configuration a build ran with, once its
+// in-repo build.yml (if any) has been merged with the config the
+// scheduler provided.
+type Config struct {
+	Config builds.Config `json:"config"`
+}
+
+func (Config) EventType() EventType { return "config" }
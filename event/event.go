@@ -0,0 +1,12 @@
+package event
+
+// EventType identifies the kind of payload an Event carries (e.g. "log",
+// "config"), and doubles as the SSE `event:` field when the event is
+// streamed out over HTTP.
+type EventType string
+
+// Event is anything that can be emitted on a Hub. Implementations are
+// plain structs that marshal to JSON as their payload.
+type Event interface {
+	EventType() EventType
+}
@@ -0,0 +1,9 @@
+package event
+
+// Log reports a chunk of a build's stdout/stderr as it runs, so that it
+// can be tailed live by whoever's watching the build (e.g. over SSE).
+type Log struct {
+	Payload string `json:"payload"`
+}
+
+func (Log) EventType() EventType { return "log" }
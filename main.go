@@ -3,23 +3,29 @@ package main
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"time"
 
 	WardenClient "github.com/cloudfoundry-incubator/garden/client"
 	WardenConnection "github.com/cloudfoundry-incubator/garden/client/connection"
+	"github.com/pivotal-golang/lager"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/grouper"
 	"github.com/tedsuo/ifrit/http_server"
 	"github.com/tedsuo/ifrit/sigmon"
 
+	"github.com/winston-ci/prole/agent"
 	"github.com/winston-ci/prole/api"
+	"github.com/winston-ci/prole/artifact"
 	"github.com/winston-ci/prole/builder"
 	"github.com/winston-ci/prole/checker"
 	"github.com/winston-ci/prole/config"
 	"github.com/winston-ci/prole/outputter"
 	"github.com/winston-ci/prole/scheduler"
 	"github.com/winston-ci/prole/sourcefetcher"
+	"github.com/winston-ci/prole/worker"
 )
 
 var listenAddr = flag.String(
@@ -40,22 +46,76 @@ var wardenAddr = flag.String(
 	"warden API connection address",
 )
 
+var backendName = flag.String(
+	"backend",
+	"garden",
+	"container backend to use (garden or docker)",
+)
+
+var dockerAddr = flag.String(
+	"dockerAddr",
+	"unix:///var/run/docker.sock",
+	"docker API connection address (only used when -backend=docker)",
+)
+
 var resourceTypes = flag.String(
 	"resourceTypes",
 	`{"git":"winston/git-resource","raw":"winston/raw-resource"}`,
 	"map of resource type to its docker image",
 )
 
+var artifactsDir = flag.String(
+	"artifactsDir",
+	"/tmp/prole-artifacts",
+	"directory in which to store uploaded build artifacts",
+)
+
+var artifactsTTL = flag.Duration(
+	"artifactsTTL",
+	24*time.Hour,
+	"how long an uploaded build artifact is kept around for before being collected (0 to keep forever)",
+)
+
+var serverAddr = flag.String(
+	"server",
+	"",
+	"address of the scheduler to dial out to (only used when -canary=true)",
+)
+
+var platform = flag.String(
+	"platform",
+	"linux/amd64",
+	"platform label to register with the server when -canary=true",
+)
+
+var maxProcs = flag.Int(
+	"maxProcs",
+	0,
+	"maximum number of builds to run at once when -canary=true (0 means unbounded)",
+)
+
+var canary = flag.Bool(
+	"canary",
+	false,
+	"dial out to -server over jsonrpc2 and receive builds as RPCs, instead of listening for HTTP requests",
+)
+
+var containerGraceTime = flag.Duration(
+	"containerGraceTime",
+	5*time.Minute,
+	"how long a check/fetch/build container is kept around after it was last touched before being destroyed (0 means never)",
+)
+
 func main() {
 	flag.Parse()
 
-	wardenClient := WardenClient.New(&WardenConnection.Info{
-		Network: *wardenNetwork,
-		Addr:    *wardenAddr,
-	})
+	backend, err := newBackend()
+	if err != nil {
+		log.Fatalln("failed to initialize container backend:", err)
+	}
 
 	resourceTypesMap := map[string]string{}
-	err := json.Unmarshal([]byte(*resourceTypes), &resourceTypesMap)
+	err = json.Unmarshal([]byte(*resourceTypes), &resourceTypesMap)
 	if err != nil {
 		log.Fatalln("failed to parse resource types:", err)
 	}
@@ -68,26 +128,59 @@ func main() {
 		})
 	}
 
-	sourceFetcher := sourcefetcher.NewSourceFetcher(resourceTypesConfig, wardenClient)
-	outputter := outputter.NewOutputter(resourceTypesConfig, wardenClient)
-	builder := builder.NewBuilder(sourceFetcher, outputter, wardenClient)
+	artifacts, err := artifact.NewDiskRepository(*artifactsDir, *artifactsTTL)
+	if err != nil {
+		log.Fatalln("failed to initialize artifact repository:", err)
+	}
+
+	sourceFetcher := sourcefetcher.NewSourceFetcher(resourceTypesConfig, backend, *containerGraceTime)
+	outputter := outputter.NewOutputter(resourceTypesConfig, backend, *containerGraceTime)
+	builder := builder.NewBuilder(sourceFetcher, outputter, backend, artifacts, *containerGraceTime)
 
-	checker := checker.NewChecker(resourceTypesConfig, wardenClient)
+	checker := checker.NewChecker(resourceTypesConfig, backend, *containerGraceTime)
 
-	scheduler := scheduler.NewScheduler(builder)
+	logger := lager.NewLogger("prole")
+	logger.RegisterSink(lager.NewWriterSink(os.Stdout, lager.INFO))
 
-	handler, err := api.New(scheduler, checker)
+	// nothing is in flight yet this early in startup, so any prole-owned
+	// container we find belongs to a previous, now-dead process
+	err = worker.ReconcileContainers(logger, backend, nil)
 	if err != nil {
-		log.Fatalln("failed to initialize handler:", err)
+		log.Println("failed to reconcile containers:", err)
+	}
+
+	if *artifactsTTL != 0 {
+		go collectArtifacts(artifacts, *artifactsTTL)
+	}
+
+	var runner ifrit.Runner
+
+	if *canary {
+		runner = agent.New(logger, *serverAddr, *platform, *maxProcs, builder)
+	} else {
+		scheduler := scheduler.NewScheduler(builder)
+
+		drain := make(chan struct{})
+
+		handler, err := api.New(logger, scheduler, checker, artifacts, drain)
+		if err != nil {
+			log.Fatalln("failed to initialize handler:", err)
+		}
+
+		runner = http_server.New(*listenAddr, handler)
 	}
 
 	group := grouper.EnvokeGroup(grouper.RunGroup{
-		"api": http_server.New(*listenAddr, handler),
+		"prole": runner,
 	})
 
 	running := ifrit.Envoke(sigmon.New(group))
 
-	log.Println("serving api on", *listenAddr)
+	if *canary {
+		log.Println("connecting to server at", *serverAddr)
+	} else {
+		log.Println("serving api on", *listenAddr)
+	}
 
 	err = <-running.Wait()
 	if err != nil {
@@ -97,3 +190,25 @@ func main() {
 
 	log.Println("exited")
 }
+
+func collectArtifacts(artifacts artifact.Repository, ttl time.Duration) {
+	for range time.Tick(ttl / 2) {
+		artifacts.Collect()
+	}
+}
+
+func newBackend() (worker.Backend, error) {
+	switch *backendName {
+	case "docker":
+		return worker.NewDockerBackend(*dockerAddr)
+	case "garden":
+		wardenClient := WardenClient.New(&WardenConnection.Info{
+			Network: *wardenNetwork,
+			Addr:    *wardenAddr,
+		})
+
+		return worker.NewGardenBackend(wardenClient), nil
+	default:
+		return nil, fmt.Errorf("unknown backend: %s (must be garden or docker)", *backendName)
+	}
+}
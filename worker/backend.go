@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"io"
+	"time"
+)
+
+// Backend abstracts over the container runtime prole uses to run checks,
+// fetch resources, and execute builds, so that operators aren't required
+// to run Garden in order to run prole.
+type Backend interface {
+	Create(ContainerSpec) (Container, error)
+	Destroy(handle string) error
+
+	Lookup(handle string) (Container, error)
+	Containers(properties Properties) ([]Container, error)
+}
+
+type Container interface {
+	Handle() string
+
+	StreamIn(dst string, src io.Reader) error
+	StreamOut(src string) (io.ReadCloser, error)
+
+	Run(ProcessSpec) (Process, error)
+	Attach(processID uint32) (Process, error)
+
+	// Properties also serves as a keepalive: calling it resets the
+	// container's GraceTime, the same way polling a Garden container's
+	// Info does.
+	Properties() (Properties, error)
+}
+
+// Process is a running (or completed) invocation of a ProcessSpec inside
+// a Container. Its payloads are delivered in the same shape regardless of
+// which Backend produced it.
+type Process interface {
+	ID() uint32
+	Stream() <-chan ProcessPayload
+}
+
+type Properties map[string]string
+
+type ContainerSpec struct {
+	// Image is resolved by each Backend in its own way: a Docker image
+	// reference for the Docker backend, a rootfs path for Garden.
+	Image string
+
+	Properties Properties
+
+	// GraceTime is how long the container is kept around after it was
+	// last touched (created, or had Properties called on it) before
+	// being destroyed. On the docker backend, zero or less means never;
+	// the Garden backend hands the value to Garden unchanged.
+	GraceTime time.Duration
+}
+
+type ProcessSpec struct {
+	Path string
+	Args []string
+	Env  []string
+
+	Dir string
+}
+
+type ProcessStreamSource int
+
+const (
+	ProcessStreamSourceStdout ProcessStreamSource = iota
+	ProcessStreamSourceStderr
+)
+
+type ProcessPayload struct {
+	Source ProcessStreamSource
+	Data   []byte
+
+	ExitStatus *uint32
+}
@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	dockerclient "github.com/docker/docker/client"
+)
+
+// NewDockerBackend adapts the Docker Engine API to the Backend interface,
+// so that prole can run against a plain Docker host instead of Garden.
+func NewDockerBackend(addr string) (Backend, error) {
+	cli, err := dockerclient.NewClientWithOpts(
+		dockerclient.WithHost(addr),
+		dockerclient.WithAPIVersionNegotiation(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &dockerBackend{client: cli}
+	backend.reaper = newReaper(backend)
+
+	return backend, nil
+}
+
+type dockerBackend struct {
+	client *dockerclient.Client
+
+	// reaper destroys Docker containers whose GraceTime has elapsed,
+	// since the Docker API has no native equivalent of Garden's.
+	reaper *reaper
+}
+
+func (backend *dockerBackend) Create(spec ContainerSpec) (Container, error) {
+	ctx := context.Background()
+
+	reader, err := backend.client.ImagePull(ctx, spec.Image, types.ImagePullOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	io.Copy(ioutil.Discard, reader)
+	reader.Close()
+
+	created, err := backend.client.ContainerCreate(
+		ctx,
+		&container.Config{
+			Image:  spec.Image,
+			Labels: spec.Properties,
+			Cmd:    []string{"sleep", "infinity"},
+		},
+		&container.HostConfig{},
+		nil,
+		nil,
+		"",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = backend.client.ContainerStart(ctx, created.ID, types.ContainerStartOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	backend.reaper.track(created.ID, spec.GraceTime)
+
+	return &dockerContainer{client: backend.client, id: created.ID, reaper: backend.reaper, graceTime: spec.GraceTime}, nil
+}
+
+func (backend *dockerBackend) Destroy(handle string) error {
+	backend.reaper.forget(handle)
+
+	return backend.client.ContainerRemove(context.Background(), handle, types.ContainerRemoveOptions{
+		Force: true,
+	})
+}
+
+func (backend *dockerBackend) Lookup(handle string) (Container, error) {
+	_, err := backend.client.ContainerInspect(context.Background(), handle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dockerContainer{client: backend.client, id: handle}, nil
+}
+
+func (backend *dockerBackend) Containers(properties Properties) ([]Container, error) {
+	filterArgs := filters.NewArgs()
+	for name, value := range properties {
+		filterArgs.Add("label", name+"="+value)
+	}
+
+	summaries, err := backend.client.ContainerList(context.Background(), types.ContainerListOptions{
+		All:     true,
+		Filters: filterArgs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]Container, len(summaries))
+	for i, summary := range summaries {
+		containers[i] = &dockerContainer{client: backend.client, id: summary.ID}
+	}
+
+	return containers, nil
+}
+
+type dockerContainer struct {
+	client *dockerclient.Client
+	id     string
+
+	reaper    *reaper
+	graceTime time.Duration
+}
+
+func (container *dockerContainer) Handle() string {
+	return container.id
+}
+
+func (container *dockerContainer) StreamIn(dst string, src io.Reader) error {
+	return container.client.CopyToContainer(context.Background(), container.id, dst, src, types.CopyToContainerOptions{})
+}
+
+func (container *dockerContainer) StreamOut(src string) (io.ReadCloser, error) {
+	reader, _, err := container.client.CopyFromContainer(context.Background(), container.id, src)
+	return reader, err
+}
+
+func (container *dockerContainer) Run(spec ProcessSpec) (Process, error) {
+	ctx := context.Background()
+
+	exec, err := container.client.ContainerExecCreate(ctx, container.id, types.ExecConfig{
+		Cmd:          append([]string{spec.Path}, spec.Args...),
+		Env:          spec.Env,
+		WorkingDir:   spec.Dir,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attached, err := container.client.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return nil, err
+	}
+
+	return newDockerProcess(container.client, exec.ID, attached), nil
+}
+
+func (container *dockerContainer) Attach(processID uint32) (Process, error) {
+	return nil, errors.New("attaching to a previously-started process is not supported by the docker backend")
+}
+
+func (container *dockerContainer) Properties() (Properties, error) {
+	if container.reaper != nil {
+		container.reaper.track(container.id, container.graceTime)
+	}
+
+	info, err := container.client.ContainerInspect(context.Background(), container.id)
+	if err != nil {
+		return nil, err
+	}
+
+	return Properties(info.Config.Labels), nil
+}
+
+// execIDToProcessID gives exec IDs (which are strings) a stable uint32
+// identity, since Process.ID is shared across backends.
+func execIDToProcessID(execID string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(execID))
+	return h.Sum32()
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	dockerclient "github.com/docker/docker/client"
+)
+
+// dockerStreamStdout/dockerStreamStderr are the stream-type byte values
+// used in the 8-byte frame header of Docker's multiplexed attach stream.
+const (
+	dockerStreamStdout = 1
+	dockerStreamStderr = 2
+)
+
+type dockerProcess struct {
+	id     uint32
+	stream <-chan ProcessPayload
+}
+
+func newDockerProcess(client *dockerclient.Client, execID string, attached types.HijackedResponse) *dockerProcess {
+	payloads := make(chan ProcessPayload)
+
+	go func() {
+		defer close(payloads)
+		defer attached.Close()
+
+		demuxDockerStream(attached.Reader, payloads)
+
+		inspected, err := client.ContainerExecInspect(context.Background(), execID)
+		if err != nil {
+			return
+		}
+
+		exitStatus := uint32(inspected.ExitCode)
+		payloads <- ProcessPayload{ExitStatus: &exitStatus}
+	}()
+
+	return &dockerProcess{
+		id:     execIDToProcessID(execID),
+		stream: payloads,
+	}
+}
+
+func (process *dockerProcess) ID() uint32 {
+	return process.id
+}
+
+func (process *dockerProcess) Stream() <-chan ProcessPayload {
+	return process.stream
+}
+
+// demuxDockerStream splits Docker's multiplexed stdout/stderr stream
+// (8-byte header: 1 type byte, 3 reserved, 4 big-endian length) into
+// individual payloads, returning once the underlying reader ends.
+func demuxDockerStream(src io.Reader, dest chan<- ProcessPayload) {
+	header := make([]byte, 8)
+
+	for {
+		_, err := io.ReadFull(src, header)
+		if err != nil {
+			return
+		}
+
+		size := binary.BigEndian.Uint32(header[4:8])
+		frame := make([]byte, size)
+
+		_, err = io.ReadFull(src, frame)
+		if err != nil {
+			return
+		}
+
+		source := ProcessStreamSourceStdout
+		if header[0] == dockerStreamStderr {
+			source = ProcessStreamSourceStderr
+		}
+
+		dest <- ProcessPayload{Source: source, Data: frame}
+	}
+}
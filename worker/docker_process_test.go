@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("demuxDockerStream", func() {
+	frame := func(streamType byte, data string) []byte {
+		header := make([]byte, 8)
+		header[0] = streamType
+		binary.BigEndian.PutUint32(header[4:8], uint32(len(data)))
+		return append(header, []byte(data)...)
+	}
+
+	It("splits stdout and stderr frames out of the multiplexed stream", func() {
+		var src bytes.Buffer
+		src.Write(frame(dockerStreamStdout, "hello\n"))
+		src.Write(frame(dockerStreamStderr, "uh oh\n"))
+
+		dest := make(chan ProcessPayload, 2)
+
+		demuxDockerStream(&src, dest)
+		close(dest)
+
+		first := <-dest
+		Ω(first.Source).Should(Equal(ProcessStreamSourceStdout))
+		Ω(string(first.Data)).Should(Equal("hello\n"))
+
+		second := <-dest
+		Ω(second.Source).Should(Equal(ProcessStreamSourceStderr))
+		Ω(string(second.Data)).Should(Equal("uh oh\n"))
+	})
+})
@@ -0,0 +1,77 @@
+package fakeworker
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/winston-ci/prole/worker"
+)
+
+type FakeBackend struct {
+	WhenCreating func(worker.ContainerSpec) (worker.Container, error)
+
+	lock      sync.Mutex
+	created   []worker.ContainerSpec
+	handles   []worker.Container
+	destroyed []string
+}
+
+func New() *FakeBackend {
+	return &FakeBackend{}
+}
+
+func (backend *FakeBackend) Create(spec worker.ContainerSpec) (worker.Container, error) {
+	backend.lock.Lock()
+	backend.created = append(backend.created, spec)
+	handle := fmt.Sprintf("fake-handle-%d", len(backend.handles))
+	backend.lock.Unlock()
+
+	var container worker.Container
+	var err error
+
+	if backend.WhenCreating != nil {
+		container, err = backend.WhenCreating(spec)
+	} else {
+		container = NewFakeContainer(handle)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	backend.lock.Lock()
+	backend.handles = append(backend.handles, container)
+	backend.lock.Unlock()
+
+	return container, nil
+}
+
+func (backend *FakeBackend) Created() []worker.ContainerSpec {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	return backend.created
+}
+
+func (backend *FakeBackend) Destroy(handle string) error {
+	backend.lock.Lock()
+	backend.destroyed = append(backend.destroyed, handle)
+	backend.lock.Unlock()
+
+	return nil
+}
+
+func (backend *FakeBackend) Destroyed() []string {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	return backend.destroyed
+}
+
+func (backend *FakeBackend) Lookup(handle string) (worker.Container, error) {
+	return NewFakeContainer(handle), nil
+}
+
+func (backend *FakeBackend) Containers(worker.Properties) ([]worker.Container, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	return backend.handles, nil
+}
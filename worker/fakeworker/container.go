@@ -0,0 +1,103 @@
+package fakeworker
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/winston-ci/prole/worker"
+)
+
+type FakeContainer struct {
+	WhenRunning     func(worker.ProcessSpec) (worker.Process, error)
+	WhenStreamingIn func(dst string, src io.Reader) error
+
+	handle string
+
+	lock      sync.Mutex
+	streamsIn []streamIn
+}
+
+type streamIn struct {
+	Destination string
+	Contents    []byte
+}
+
+func NewFakeContainer(handle string) *FakeContainer {
+	return &FakeContainer{handle: handle}
+}
+
+func (container *FakeContainer) Handle() string {
+	return container.handle
+}
+
+func (container *FakeContainer) StreamIn(dst string, src io.Reader) error {
+	if container.WhenStreamingIn != nil {
+		return container.WhenStreamingIn(dst, src)
+	}
+
+	contents, err := ioutil.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	container.lock.Lock()
+	container.streamsIn = append(container.streamsIn, streamIn{Destination: dst, Contents: contents})
+	container.lock.Unlock()
+
+	return nil
+}
+
+func (container *FakeContainer) StreamedIn() []string {
+	container.lock.Lock()
+	defer container.lock.Unlock()
+
+	destinations := make([]string, len(container.streamsIn))
+	for i, s := range container.streamsIn {
+		destinations[i] = s.Destination
+	}
+
+	return destinations
+}
+
+func (container *FakeContainer) StreamOut(src string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(nil)), nil
+}
+
+func (container *FakeContainer) Run(spec worker.ProcessSpec) (worker.Process, error) {
+	if container.WhenRunning != nil {
+		return container.WhenRunning(spec)
+	}
+
+	exitStatus := uint32(0)
+
+	payloads := make(chan worker.ProcessPayload, 1)
+	payloads <- worker.ProcessPayload{ExitStatus: &exitStatus}
+	close(payloads)
+
+	return &FakeProcess{id: 1, stream: payloads}, nil
+}
+
+func (container *FakeContainer) Attach(processID uint32) (worker.Process, error) {
+	return &FakeProcess{id: processID, stream: make(chan worker.ProcessPayload)}, nil
+}
+
+func (container *FakeContainer) Properties() (worker.Properties, error) {
+	return worker.Properties{}, nil
+}
+
+type FakeProcess struct {
+	id     uint32
+	stream <-chan worker.ProcessPayload
+}
+
+// NewFakeProcess wraps a pre-built stream of payloads as a Process, for
+// tests that want to script exactly what a container's Run emits.
+func NewFakeProcess(id uint32, stream <-chan worker.ProcessPayload) *FakeProcess {
+	return &FakeProcess{id: id, stream: stream}
+}
+
+func (process *FakeProcess) ID() uint32 { return process.id }
+
+func (process *FakeProcess) Stream() <-chan worker.ProcessPayload { return process.stream }
@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/cloudfoundry-incubator/garden/warden"
+)
+
+// NewGardenBackend adapts an existing Garden/Warden client to the Backend
+// interface, so that it keeps working unchanged as the -backend=garden
+// default.
+func NewGardenBackend(client warden.Client) Backend {
+	return &gardenBackend{client: client}
+}
+
+type gardenBackend struct {
+	client warden.Client
+}
+
+func (backend *gardenBackend) Create(spec ContainerSpec) (Container, error) {
+	container, err := backend.client.Create(warden.ContainerSpec{
+		RootFSPath: spec.Image,
+		Properties: warden.Properties(spec.Properties),
+		GraceTime:  spec.GraceTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &gardenContainer{container: container}, nil
+}
+
+func (backend *gardenBackend) Destroy(handle string) error {
+	return backend.client.Destroy(handle)
+}
+
+func (backend *gardenBackend) Lookup(handle string) (Container, error) {
+	container, err := backend.client.Lookup(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gardenContainer{container: container}, nil
+}
+
+func (backend *gardenBackend) Containers(properties Properties) ([]Container, error) {
+	containers, err := backend.client.Containers(warden.Properties(properties))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := make([]Container, len(containers))
+	for i, container := range containers {
+		wrapped[i] = &gardenContainer{container: container}
+	}
+
+	return wrapped, nil
+}
+
+type gardenContainer struct {
+	container warden.Container
+}
+
+type cleanupReadCloser struct {
+	io.ReadCloser
+	cleanup func()
+}
+
+func (rc *cleanupReadCloser) Close() error {
+	err := rc.ReadCloser.Close()
+	rc.cleanup()
+	return err
+}
+
+func (container *gardenContainer) Handle() string {
+	return container.container.Handle()
+}
+
+// StreamIn bridges the stream-oriented Backend API onto Garden's
+// path-to-path CopyIn by spooling src to a temporary file on the host.
+func (container *gardenContainer) StreamIn(dst string, src io.Reader) error {
+	tmpFile, err := ioutil.TempFile("", "garden-stream-in")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	_, err = io.Copy(tmpFile, src)
+	if err != nil {
+		return err
+	}
+
+	return container.container.CopyIn(tmpFile.Name(), dst)
+}
+
+func (container *gardenContainer) StreamOut(src string) (io.ReadCloser, error) {
+	tmpDir, err := ioutil.TempDir("", "garden-stream-out")
+	if err != nil {
+		return nil, err
+	}
+
+	err = container.container.CopyOut(src, tmpDir, "")
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+
+	stream, err := TarDirectory(tmpDir)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+
+	return &cleanupReadCloser{ReadCloser: stream, cleanup: func() { os.RemoveAll(tmpDir) }}, nil
+}
+
+func (container *gardenContainer) Run(spec ProcessSpec) (Process, error) {
+	processID, stream, err := container.container.Run(warden.ProcessSpec{
+		Path: spec.Path,
+		Args: spec.Args,
+		Env:  spec.Env,
+		Dir:  spec.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &gardenProcess{id: processID, stream: translateStream(stream)}, nil
+}
+
+func (container *gardenContainer) Attach(processID uint32) (Process, error) {
+	stream, err := container.container.Attach(processID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gardenProcess{id: processID, stream: translateStream(stream)}, nil
+}
+
+func (container *gardenContainer) Properties() (Properties, error) {
+	info, err := container.container.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	return Properties(info.Properties), nil
+}
+
+func translateStream(wardenStream <-chan warden.ProcessStream) <-chan ProcessPayload {
+	payloads := make(chan ProcessPayload)
+
+	go func() {
+		defer close(payloads)
+
+		for chunk := range wardenStream {
+			payload := ProcessPayload{
+				Data:       chunk.Data,
+				ExitStatus: chunk.ExitStatus,
+			}
+
+			if chunk.Source == warden.ProcessStreamSourceStderr {
+				payload.Source = ProcessStreamSourceStderr
+			} else {
+				payload.Source = ProcessStreamSourceStdout
+			}
+
+			payloads <- payload
+		}
+	}()
+
+	return payloads
+}
+
+type gardenProcess struct {
+	id     uint32
+	stream <-chan ProcessPayload
+}
+
+func (process *gardenProcess) ID() uint32                    { return process.id }
+func (process *gardenProcess) Stream() <-chan ProcessPayload { return process.stream }
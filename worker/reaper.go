@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"sync"
+	"time"
+)
+
+const reaperSweepInterval = time.Second
+
+// reaper destroys containers that haven't been kept alive within their
+// GraceTime. Garden containers have native grace-time support; this
+// exists for backends, like Docker, that don't.
+type reaper struct {
+	backend Backend
+
+	lock      sync.Mutex
+	deadlines map[string]time.Time
+}
+
+func newReaper(backend Backend) *reaper {
+	r := &reaper{
+		backend:   backend,
+		deadlines: make(map[string]time.Time),
+	}
+
+	go r.sweep()
+
+	return r
+}
+
+// track (re)sets handle's deadline to graceTime from now. A graceTime of
+// zero or less means the container is never reaped.
+func (r *reaper) track(handle string, graceTime time.Duration) {
+	if graceTime <= 0 {
+		return
+	}
+
+	r.lock.Lock()
+	r.deadlines[handle] = time.Now().Add(graceTime)
+	r.lock.Unlock()
+}
+
+func (r *reaper) forget(handle string) {
+	r.lock.Lock()
+	delete(r.deadlines, handle)
+	r.lock.Unlock()
+}
+
+func (r *reaper) sweep() {
+	for range time.Tick(reaperSweepInterval) {
+		now := time.Now()
+
+		r.lock.Lock()
+		var expired []string
+		for handle, deadline := range r.deadlines {
+			if now.After(deadline) {
+				expired = append(expired, handle)
+			}
+		}
+		for _, handle := range expired {
+			delete(r.deadlines, handle)
+		}
+		r.lock.Unlock()
+
+		for _, handle := range expired {
+			r.backend.Destroy(handle)
+		}
+	}
+}
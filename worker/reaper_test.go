@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type destroyRecordingBackend struct {
+	Backend
+
+	lock      sync.Mutex
+	destroyed []string
+}
+
+func (b *destroyRecordingBackend) Destroy(handle string) error {
+	b.lock.Lock()
+	b.destroyed = append(b.destroyed, handle)
+	b.lock.Unlock()
+	return nil
+}
+
+func (b *destroyRecordingBackend) Destroyed() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.destroyed
+}
+
+var _ = Describe("reaper", func() {
+	It("destroys a tracked container once its grace time elapses", func() {
+		backend := &destroyRecordingBackend{}
+
+		r := newReaper(backend)
+		r.track("some-handle", 10*time.Millisecond)
+
+		Eventually(backend.Destroyed, 2*reaperSweepInterval).Should(ConsistOf("some-handle"))
+	})
+
+	It("never destroys a container tracked with a zero grace time", func() {
+		backend := &destroyRecordingBackend{}
+
+		r := newReaper(backend)
+		r.track("some-handle", 0)
+
+		Consistently(backend.Destroyed, 2*reaperSweepInterval).Should(BeEmpty())
+	})
+
+	It("stops tracking a container once it's forgotten", func() {
+		backend := &destroyRecordingBackend{}
+
+		r := newReaper(backend)
+		r.track("some-handle", 10*time.Millisecond)
+		r.forget("some-handle")
+
+		Consistently(backend.Destroyed, 2*reaperSweepInterval).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,41 @@
+package worker
+
+import "github.com/pivotal-golang/lager"
+
+// OwnerProperty namespaces the containers prole creates, so that a
+// reconciliation pass can find them among everything else running on the
+// backend without touching unrelated containers.
+const OwnerProperty = "prole:owner"
+
+const OwnerPropertyValue = "prole"
+
+// ReconcileContainers destroys any prole-owned container whose handle
+// isn't present in activeHandles. It's meant to be run once at startup,
+// to clean up containers left behind by a prole process that died
+// mid-build before its grace time (or a disabled one) could apply.
+func ReconcileContainers(logger lager.Logger, backend Backend, activeHandles map[string]bool) error {
+	containers, err := backend.Containers(Properties{OwnerProperty: OwnerPropertyValue})
+	if err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		handle := container.Handle()
+
+		if activeHandles[handle] {
+			continue
+		}
+
+		log := logger.Session("reap-orphan", lager.Data{"handle": handle})
+
+		err := backend.Destroy(handle)
+		if err != nil {
+			log.Error("failed", err)
+			continue
+		}
+
+		log.Info("destroyed")
+	}
+
+	return nil
+}
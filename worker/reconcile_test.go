@@ -0,0 +1,32 @@
+package worker_test
+
+import (
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/winston-ci/prole/worker"
+	"github.com/winston-ci/prole/worker/fakeworker"
+)
+
+var _ = Describe("ReconcileContainers", func() {
+	It("destroys prole-owned containers that aren't active, and leaves active ones alone", func() {
+		backend := fakeworker.New()
+
+		orphan, err := backend.Create(worker.ContainerSpec{})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		active, err := backend.Create(worker.ContainerSpec{})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		err = worker.ReconcileContainers(
+			lagertest.NewTestLogger("test"),
+			backend,
+			map[string]bool{active.Handle(): true},
+		)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(backend.Destroyed()).Should(ConsistOf(orphan.Handle()))
+	})
+})
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// TarDirectory streams dir as a tar archive, so that backends whose
+// native copy-out API works on the host filesystem (e.g. Garden's
+// CopyOut) can still satisfy the stream-oriented StreamOut, and so that
+// callers of StreamIn can hand over a directory of fetched source.
+func TarDirectory(dir string) (io.ReadCloser, error) {
+	pipeReader, pipeWriter := io.Pipe()
+
+	go func() {
+		tarWriter := tar.NewWriter(pipeWriter)
+
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+
+			if relPath == "." {
+				return nil
+			}
+
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+
+			header.Name = relPath
+
+			err = tarWriter.WriteHeader(header)
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				return nil
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+
+			defer file.Close()
+
+			_, err = io.Copy(tarWriter, file)
+			return err
+		})
+
+		if err != nil {
+			pipeWriter.CloseWithError(err)
+			return
+		}
+
+		pipeWriter.CloseWithError(tarWriter.Close())
+	}()
+
+	return pipeReader, nil
+}